@@ -0,0 +1,132 @@
+// Package client adapts a go-scm client to the narrower interface the
+// updater needs, so the updater can be tested against an in-memory fake
+// instead of a real SCM API.
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// FileUpdate is the new content to write for a file, together with the blob
+// SHA of the content it's replacing (as returned by GetFile), which the SCM
+// API requires to detect a concurrent conflicting edit.
+type FileUpdate struct {
+	Content []byte
+	SHA     string
+}
+
+// SCM is the subset of source-control operations the updater needs in order
+// to rewrite a file and open a pull request for the change.
+type SCM interface {
+	// GetFile returns a file's content and its blob SHA, which must be
+	// passed back as the sha argument to UpdateFile (or as a FileUpdate.SHA
+	// to UpdateFiles) when replacing it.
+	GetFile(ctx context.Context, repo, path, ref string) (content []byte, sha string, err error)
+	GetBranchHead(ctx context.Context, repo, branch string) (string, error)
+	CreateBranch(ctx context.Context, repo, branch, sha string) error
+	UpdateFile(ctx context.Context, repo, path, branch string, content []byte, sha string) error
+	// UpdateFiles writes every entry in files to branch. go-scm doesn't
+	// expose a raw blob/tree/commit API to fold several file edits into a
+	// single commit, so this writes one commit per file via UpdateFile.
+	UpdateFiles(ctx context.Context, repo, branch string, files map[string]FileUpdate) error
+	CreatePullRequest(ctx context.Context, repo string, input *scm.PullRequestInput) (*scm.PullRequest, error)
+	// UpdatePullRequest updates the title and body of an already-open pull
+	// request, used to refresh it when a push reuses its branch instead of
+	// opening a new one.
+	UpdatePullRequest(ctx context.Context, repo string, number int, input *scm.PullRequestInput) error
+	// ListPullRequests returns the open pull requests in repo whose head is
+	// branch, so a caller can reuse one instead of opening a duplicate.
+	ListPullRequests(ctx context.Context, repo, branch string) ([]*scm.PullRequest, error)
+}
+
+// Client adapts a *scm.Client to the SCM interface.
+type Client struct {
+	scm *scm.Client
+}
+
+// New creates a Client wrapping a go-scm client.
+func New(c *scm.Client) *Client {
+	return &Client{scm: c}
+}
+
+func (c *Client) GetFile(ctx context.Context, repo, path, ref string) ([]byte, string, error) {
+	content, _, err := c.scm.Contents.Find(ctx, repo, path, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	return content.Data, content.Sha, nil
+}
+
+func (c *Client) GetBranchHead(ctx context.Context, repo, branch string) (string, error) {
+	ref, _, err := c.scm.Git.FindBranch(ctx, repo, branch)
+	if err != nil {
+		return "", err
+	}
+	return ref.Sha, nil
+}
+
+func (c *Client) CreateBranch(ctx context.Context, repo, branch, sha string) error {
+	_, _, err := c.scm.Git.CreateRef(ctx, repo, "refs/heads/"+branch, sha)
+	return err
+}
+
+func (c *Client) UpdateFile(ctx context.Context, repo, path, branch string, content []byte, sha string) error {
+	_, err := c.scm.Contents.Update(ctx, repo, path, &scm.ContentParams{
+		Branch:  branch,
+		Data:    content,
+		Sha:     sha,
+		Message: "Automated image update",
+	})
+	return err
+}
+
+// UpdateFiles writes every entry in files to branch, one Contents.Update
+// call (and so one commit) per file: go-scm's GitService has no raw
+// blob/tree/commit API to batch them into a single commit the way a direct
+// git client could. Paths are written in a deterministic order so repeated
+// runs against the same input produce the same commit sequence.
+func (c *Client) UpdateFiles(ctx context.Context, repo, branch string, files map[string]FileUpdate) error {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		update := files[path]
+		if err := c.UpdateFile(ctx, repo, path, branch, update.Content, update.SHA); err != nil {
+			return fmt.Errorf("failed to update %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) CreatePullRequest(ctx context.Context, repo string, input *scm.PullRequestInput) (*scm.PullRequest, error) {
+	pr, _, err := c.scm.PullRequests.Create(ctx, repo, input)
+	return pr, err
+}
+
+func (c *Client) UpdatePullRequest(ctx context.Context, repo string, number int, input *scm.PullRequestInput) error {
+	_, _, err := c.scm.PullRequests.Update(ctx, repo, number, input)
+	return err
+}
+
+// ListPullRequests returns the open pull requests in repo whose head is
+// branch.
+func (c *Client) ListPullRequests(ctx context.Context, repo, branch string) ([]*scm.PullRequest, error) {
+	prs, _, err := c.scm.PullRequests.List(ctx, repo, &scm.PullRequestListOptions{Open: true})
+	if err != nil {
+		return nil, err
+	}
+	var matched []*scm.PullRequest
+	for _, pr := range prs {
+		if pr.Source == branch {
+			matched = append(matched, pr)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,143 @@
+// client_test exercises Client itself against a stub GitHub API server,
+// rather than pkg/client/mock (which only fakes our own narrower SCM
+// interface), so a Client method built on a go-scm call that doesn't
+// actually exist or doesn't match its real signature is caught here too.
+package client_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/github"
+
+	"github.com/gitops-tools/image-hooks/pkg/client"
+)
+
+func newTestClient(t *testing.T, mux *http.ServeMux) *client.Client {
+	t.Helper()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	scmClient, err := github.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.New(scmClient)
+}
+
+func TestClientGetFile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/testorg/testrepo/contents/test.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"content":  base64.StdEncoding.EncodeToString([]byte("test: content\n")),
+			"encoding": "base64",
+			"sha":      "abc123",
+		})
+	})
+	c := newTestClient(t, mux)
+
+	got, sha, err := c.GetFile(context.Background(), "testorg/testrepo", "test.yaml", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "test: content\n"; string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if want := "abc123"; sha != want {
+		t.Fatalf("got blob sha %q, want %q", sha, want)
+	}
+}
+
+func TestClientCreateBranch(t *testing.T) {
+	var gotBody map[string]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/testorg/testrepo/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ref":    "refs/heads/new-branch",
+			"object": map[string]string{"sha": "abc123"},
+		})
+	})
+	c := newTestClient(t, mux)
+
+	if err := c.CreateBranch(context.Background(), "testorg/testrepo", "new-branch", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if gotBody["ref"] != "refs/heads/new-branch" {
+		t.Fatalf("got ref %q, want %q", gotBody["ref"], "refs/heads/new-branch")
+	}
+}
+
+func TestClientUpdateFilesWritesEachFileInOrder(t *testing.T) {
+	var updatedPaths []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/testorg/testrepo/contents/", func(w http.ResponseWriter, r *http.Request) {
+		updatedPaths = append(updatedPaths, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content": map[string]string{"sha": "def456"},
+			"commit":  map[string]string{"sha": "def456"},
+		})
+	})
+	c := newTestClient(t, mux)
+
+	files := map[string]client.FileUpdate{
+		"b.yaml": {Content: []byte("b: content\n"), SHA: "b-sha"},
+		"a.yaml": {Content: []byte("a: content\n"), SHA: "a-sha"},
+	}
+	if err := c.UpdateFiles(context.Background(), "testorg/testrepo", "test-branch", files); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/repos/testorg/testrepo/contents/a.yaml",
+		"/repos/testorg/testrepo/contents/b.yaml",
+	}
+	if len(updatedPaths) != len(want) || updatedPaths[0] != want[0] || updatedPaths[1] != want[1] {
+		t.Fatalf("got %v, want %v", updatedPaths, want)
+	}
+}
+
+func TestClientUpdatePullRequest(t *testing.T) {
+	var gotBody map[string]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/testorg/testrepo/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{"number": 7})
+	})
+	c := newTestClient(t, mux)
+
+	err := c.UpdatePullRequest(context.Background(), "testorg/testrepo", 7, &scm.PullRequestInput{
+		Title: "updated title",
+		Body:  "updated body",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotBody["title"] != "updated title" || gotBody["body"] != "updated body" {
+		t.Fatalf("got body %v, want title/body updated", gotBody)
+	}
+}
+
+func TestClientListPullRequestsFiltersByHead(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/testorg/testrepo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"number": 1, "head": map[string]string{"ref": "img-abc123"}},
+			{"number": 2, "head": map[string]string{"ref": "img-def456"}},
+		})
+	})
+	c := newTestClient(t, mux)
+
+	prs, err := c.ListPullRequests(context.Background(), "testorg/testrepo", "img-abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 1 {
+		t.Fatalf("got %d pull requests, want 1", len(prs))
+	}
+}
@@ -0,0 +1,276 @@
+// Package mock is an in-memory implementation of client.SCM for use in
+// tests, avoiding real network calls to an SCM API.
+package mock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+
+	"github.com/gitops-tools/image-hooks/pkg/client"
+)
+
+type fileKey struct {
+	repo, path, ref string
+}
+
+type branchKey struct {
+	repo, branch string
+}
+
+type fileBranchKey struct {
+	repo, path, branch string
+}
+
+// filePathKey identifies a file by repo and path only, independent of ref or
+// branch, since a blob SHA is content-addressed rather than tied to a ref.
+type filePathKey struct {
+	repo, path string
+}
+
+// blobSHA derives a stable, content-addressed SHA for content, mirroring
+// (without replicating) how a real SCM derives a blob's SHA from its bytes.
+func blobSHA(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+type createdPR struct {
+	repo  string
+	input *scm.PullRequestInput
+}
+
+type updatedPR struct {
+	repo   string
+	number int
+	input  *scm.PullRequestInput
+}
+
+// Mock is an in-memory client.SCM fake, driven by Add* setup methods and
+// inspected with the Assert*/Refute* helpers.
+type Mock struct {
+	t *testing.T
+
+	files               map[fileKey][]byte
+	blobSHAs            map[filePathKey]string
+	branchHeads         map[branchKey]string
+	createdBranches     map[branchKey]string
+	updatedFiles        map[fileBranchKey][]byte
+	pullRequests        []createdPR
+	updatedPullRequests []updatedPR
+	openPullRequests    map[branchKey]*scm.PullRequest
+
+	GetFileErr           error
+	CreateBranchErr      error
+	UpdateFileErr        error
+	CreatePullRequestErr error
+	UpdatePullRequestErr error
+	ListPullRequestsErr  error
+}
+
+// New creates a Mock ready for use in t.
+func New(t *testing.T) *Mock {
+	return &Mock{
+		t:                t,
+		files:            map[fileKey][]byte{},
+		blobSHAs:         map[filePathKey]string{},
+		branchHeads:      map[branchKey]string{},
+		createdBranches:  map[branchKey]string{},
+		updatedFiles:     map[fileBranchKey][]byte{},
+		openPullRequests: map[branchKey]*scm.PullRequest{},
+	}
+}
+
+// AddOpenPullRequest seeds repo with an already-open pull request whose head
+// is branch, so a test can exercise the reuse-instead-of-create path.
+func (m *Mock) AddOpenPullRequest(repo, branch string, number int, link string) {
+	m.openPullRequests[branchKey{repo, branch}] = &scm.PullRequest{Number: number, Source: branch, Link: link}
+}
+
+// AddFileContents seeds the contents of repo/path at ref, and its blob SHA
+// so GetFile/UpdateFile callers can be checked for passing it back correctly.
+func (m *Mock) AddFileContents(repo, path, ref string, content []byte) {
+	m.files[fileKey{repo, path, ref}] = content
+	m.blobSHAs[filePathKey{repo, path}] = blobSHA(content)
+}
+
+// AddBranchHead seeds the head SHA of repo/branch.
+func (m *Mock) AddBranchHead(repo, branch, sha string) {
+	m.branchHeads[branchKey{repo, branch}] = sha
+}
+
+func (m *Mock) GetFile(_ context.Context, repo, path, ref string) ([]byte, string, error) {
+	if m.GetFileErr != nil {
+		return nil, "", m.GetFileErr
+	}
+	return m.files[fileKey{repo, path, ref}], m.blobSHAs[filePathKey{repo, path}], nil
+}
+
+func (m *Mock) GetBranchHead(_ context.Context, repo, branch string) (string, error) {
+	return m.branchHeads[branchKey{repo, branch}], nil
+}
+
+func (m *Mock) CreateBranch(_ context.Context, repo, branch, sha string) error {
+	if m.CreateBranchErr != nil {
+		return m.CreateBranchErr
+	}
+	m.createdBranches[branchKey{repo, branch}] = sha
+	return nil
+}
+
+func (m *Mock) UpdateFile(_ context.Context, repo, path, branch string, content []byte, sha string) error {
+	if m.UpdateFileErr != nil {
+		return m.UpdateFileErr
+	}
+	if err := m.checkBlobSHA(repo, path, sha); err != nil {
+		return err
+	}
+	m.updatedFiles[fileBranchKey{repo, path, branch}] = content
+	m.blobSHAs[filePathKey{repo, path}] = blobSHA(content)
+	return nil
+}
+
+// UpdateFiles writes every entry in files to branch, checking every entry's
+// blob SHA before writing any of them.
+func (m *Mock) UpdateFiles(_ context.Context, repo, branch string, files map[string]client.FileUpdate) error {
+	if m.UpdateFileErr != nil {
+		return m.UpdateFileErr
+	}
+	for path, update := range files {
+		if err := m.checkBlobSHA(repo, path, update.SHA); err != nil {
+			return err
+		}
+	}
+	for path, update := range files {
+		m.updatedFiles[fileBranchKey{repo, path, branch}] = update.Content
+		m.blobSHAs[filePathKey{repo, path}] = blobSHA(update.Content)
+	}
+	return nil
+}
+
+// checkBlobSHA returns an error if sha doesn't match the blob SHA most
+// recently seeded or written for repo/path, catching callers that pass a
+// branch/commit SHA where a real SCM requires the file's own blob SHA.
+func (m *Mock) checkBlobSHA(repo, path, sha string) error {
+	want, ok := m.blobSHAs[filePathKey{repo, path}]
+	if !ok {
+		return nil
+	}
+	if sha != want {
+		return fmt.Errorf("update to %s/%s used sha %q, want the file's blob sha %q", repo, path, sha, want)
+	}
+	return nil
+}
+
+func (m *Mock) CreatePullRequest(_ context.Context, repo string, input *scm.PullRequestInput) (*scm.PullRequest, error) {
+	if m.CreatePullRequestErr != nil {
+		return nil, m.CreatePullRequestErr
+	}
+	m.pullRequests = append(m.pullRequests, createdPR{repo: repo, input: input})
+	return &scm.PullRequest{}, nil
+}
+
+func (m *Mock) UpdatePullRequest(_ context.Context, repo string, number int, input *scm.PullRequestInput) error {
+	if m.UpdatePullRequestErr != nil {
+		return m.UpdatePullRequestErr
+	}
+	m.updatedPullRequests = append(m.updatedPullRequests, updatedPR{repo: repo, number: number, input: input})
+	return nil
+}
+
+// ListPullRequests returns the previously-seeded open pull requests (see
+// AddOpenPullRequest) in repo whose head is branch.
+func (m *Mock) ListPullRequests(_ context.Context, repo, branch string) ([]*scm.PullRequest, error) {
+	if m.ListPullRequestsErr != nil {
+		return nil, m.ListPullRequestsErr
+	}
+	if pr, ok := m.openPullRequests[branchKey{repo, branch}]; ok {
+		return []*scm.PullRequest{pr}, nil
+	}
+	return nil, nil
+}
+
+// GetUpdatedContents returns whatever was last written to repo/path/branch
+// via UpdateFile or UpdateFiles.
+func (m *Mock) GetUpdatedContents(repo, path, branch string) []byte {
+	return m.updatedFiles[fileBranchKey{repo, path, branch}]
+}
+
+func (m *Mock) AssertBranchCreated(repo, branch, sha string) {
+	m.t.Helper()
+	got, ok := m.createdBranches[branchKey{repo, branch}]
+	if !ok {
+		m.t.Fatalf("expected branch %s/%s to be created, it was not", repo, branch)
+	}
+	if got != sha {
+		m.t.Fatalf("branch %s/%s created from %s, want %s", repo, branch, got, sha)
+	}
+}
+
+func (m *Mock) RefuteBranchCreated(repo, branch, _ string) {
+	m.t.Helper()
+	if _, ok := m.createdBranches[branchKey{repo, branch}]; ok {
+		m.t.Fatalf("expected branch %s/%s not to be created, it was", repo, branch)
+	}
+}
+
+func (m *Mock) AssertNoBranchesCreated() {
+	m.t.Helper()
+	if len(m.createdBranches) != 0 {
+		m.t.Fatalf("expected no branches to be created, got %v", m.createdBranches)
+	}
+}
+
+func (m *Mock) AssertPullRequestCreated(repo string, want *scm.PullRequestInput) {
+	m.t.Helper()
+	for _, pr := range m.pullRequests {
+		if pr.repo == repo && pr.input.Head == want.Head {
+			return
+		}
+	}
+	m.t.Fatalf("expected pull request for %s with head %s to be created, it was not", repo, want.Head)
+}
+
+func (m *Mock) RefutePullRequestCreated(repo string, want *scm.PullRequestInput) {
+	m.t.Helper()
+	for _, pr := range m.pullRequests {
+		if pr.repo == repo && pr.input.Head == want.Head {
+			m.t.Fatalf("expected pull request for %s with head %s not to be created, it was", repo, want.Head)
+		}
+	}
+}
+
+// AssertPullRequestUpdated checks that the pull request numbered number in
+// repo was updated with want's title and body.
+func (m *Mock) AssertPullRequestUpdated(repo string, number int, want *scm.PullRequestInput) {
+	m.t.Helper()
+	for _, pr := range m.updatedPullRequests {
+		if pr.repo == repo && pr.number == number && pr.input.Title == want.Title && pr.input.Body == want.Body {
+			return
+		}
+	}
+	m.t.Fatalf("expected pull request #%d in %s to be updated with %+v, it was not", number, repo, want)
+}
+
+// PullRequestCount returns how many pull requests have been created for
+// repo, so a test can assert a push reused a PR rather than opening another.
+func (m *Mock) PullRequestCount(repo string) int {
+	count := 0
+	for _, pr := range m.pullRequests {
+		if pr.repo == repo {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *Mock) AssertNoPullRequestsCreated() {
+	m.t.Helper()
+	if len(m.pullRequests) != 0 {
+		m.t.Fatalf("expected no pull requests to be created, got %d", len(m.pullRequests))
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -13,8 +16,13 @@ import (
 	"github.com/gitops-tools/image-hooks/pkg/config"
 	"github.com/gitops-tools/image-hooks/pkg/handler"
 	"github.com/gitops-tools/image-hooks/pkg/hooks"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/artifactory"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/auto"
 	"github.com/gitops-tools/image-hooks/pkg/hooks/docker"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/ghcr"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/harbor"
 	"github.com/gitops-tools/image-hooks/pkg/hooks/quay"
+	"github.com/gitops-tools/image-hooks/pkg/metrics"
 	"github.com/gitops-tools/image-hooks/pkg/updater"
 )
 
@@ -47,8 +55,34 @@ func makeHTTPCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			handler := handler.New(sugar, updater, p)
-			http.Handle("/", handler)
+			h := handler.New(sugar, updater, p, viper.GetString("parser"))
+
+			reg := prometheus.NewRegistry()
+			m := metrics.New(reg)
+			updater.SetMetrics(m)
+			h.SetMetrics(m)
+
+			var wrapped http.Handler = h
+			if viper.GetBool("require-signature") {
+				verify, ok := handler.Verifiers[viper.GetString("parser")]
+				if !ok {
+					return fmt.Errorf("signature verification is not supported for parser %q", viper.GetString("parser"))
+				}
+				secrets, err := repos.SecretsByRepo()
+				if err != nil {
+					return err
+				}
+				if len(secrets) == 0 {
+					return fmt.Errorf("--require-signature is set but no repository in %s has a secret configured", viper.GetString("config"))
+				}
+				var replay *handler.ReplayCache
+				if window := viper.GetDuration("replay-window"); window > 0 {
+					replay = handler.NewReplayCache(window)
+				}
+				wrapped = handler.RequireSignature(h, verify, p, secrets, replay)
+			}
+			http.Handle("/", wrapped)
+			http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
 			listen := fmt.Sprintf(":%d", viper.GetInt("port"))
 			sugar.Infow("quay-hooks http starting", "port", viper.GetInt("port"), "parser", viper.GetString("parser"))
 			return http.ListenAndServe(listen, nil)
@@ -65,7 +99,7 @@ func makeHTTPCmd() *cobra.Command {
 	cmd.Flags().String(
 		"parser",
 		"quay",
-		"what driver to use to parse incoming webhooks e.g. quay, docker",
+		"what driver to use to parse incoming webhooks e.g. quay, docker, ghcr, harbor, artifactory, auto",
 	)
 	logIfError(viper.BindPFlag("parser", cmd.Flags().Lookup("parser")))
 
@@ -76,6 +110,20 @@ func makeHTTPCmd() *cobra.Command {
 	)
 	logIfError(viper.BindPFlag("config", cmd.Flags().Lookup("config")))
 
+	cmd.Flags().Bool(
+		"require-signature",
+		false,
+		"reject incoming webhooks that don't verify against a configured repository secret",
+	)
+	logIfError(viper.BindPFlag("require-signature", cmd.Flags().Lookup("require-signature")))
+
+	cmd.Flags().Duration(
+		"replay-window",
+		5*time.Minute,
+		"reject a webhook delivery seen again within this window of its most recent sighting; 0 disables replay protection",
+	)
+	logIfError(viper.BindPFlag("replay-window", cmd.Flags().Lookup("replay-window")))
+
 	return cmd
 }
 
@@ -85,6 +133,14 @@ func parser() (hooks.PushEventParser, error) {
 		return quay.Parse, nil
 	case "docker":
 		return docker.Parse, nil
+	case "ghcr":
+		return ghcr.Parse, nil
+	case "harbor":
+		return harbor.Parse, nil
+	case "artifactory":
+		return artifactory.Parse, nil
+	case "auto":
+		return auto.Parse, nil
 	default:
 		return nil, fmt.Errorf("unknown parser: %s", viper.GetString("parser"))
 	}
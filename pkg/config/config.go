@@ -0,0 +1,180 @@
+// Package config parses the repository configuration file that tells the
+// updater which file, in which source repository, to rewrite in response to
+// an image push hook.
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfiguration is the top-level shape of the configuration file.
+type RepoConfiguration struct {
+	Repositories []*Repository `yaml:"repositories"`
+}
+
+// Repository maps an image (by its hook-reported name) to the file in a
+// source repository that should be updated when that image changes.
+type Repository struct {
+	// Name is matched against the hook's reported repository, e.g.
+	// "mynamespace/repository" for a Quay hook.
+	Name string `yaml:"name"`
+	// SourceRepo is the SCM repository that holds the manifest to update,
+	// e.g. "org/environments".
+	SourceRepo string `yaml:"source_repo"`
+	// SourceBranch is the branch to read the current file from, and the
+	// branch a pull request (if any) is opened against. Defaults to
+	// "master" if unset.
+	SourceBranch string `yaml:"source_branch"`
+	// FilePath is the path, within SourceRepo, of the file to update.
+	//
+	// Deprecated: set Updates instead. FilePath/UpdateKey are folded into a
+	// single-entry Updates list when Updates is empty, for backwards
+	// compatibility with existing configuration files.
+	FilePath string `yaml:"file_path"`
+	// UpdateKey is a dotted path to the YAML scalar to rewrite, e.g.
+	// "test.image".
+	//
+	// Deprecated: set Updates instead.
+	UpdateKey string `yaml:"update_key"`
+	// Updates lists the files to rewrite when this repository's image is
+	// pushed. All of them are written to the same branch (and pull
+	// request), one commit per file.
+	Updates []Update `yaml:"updates"`
+	// BranchGenerateName is a prefix used to generate a new branch name for
+	// the update. If empty, the update is committed directly to
+	// SourceBranch instead of going through a pull request.
+	BranchGenerateName string `yaml:"branch_generate_name"`
+	// BranchStrategy selects how the update branch is named: "random"
+	// (the default) appends a random suffix to BranchGenerateName, so
+	// every push opens a new branch and pull request. "deterministic"
+	// derives the branch name from SourceRepo and the update targets' file
+	// paths and keys (not the new image or tag), so repeated pushes to the
+	// same targets reuse the existing open pull request and its branch
+	// instead of opening a duplicate.
+	BranchStrategy string `yaml:"branch_strategy"`
+	// Policy restricts and selects which of a hook's reported tags should
+	// actually trigger an update. Leave unset to use the first reported
+	// tag unconditionally, as before policies existed.
+	Policy *Policy `yaml:"policy"`
+	// Secret is the shared secret used to verify this repository's
+	// incoming webhook signatures, when --require-signature is set.
+	Secret string `yaml:"secret"`
+	// SecretRef, as an alternative to Secret, reads the secret from
+	// elsewhere at load time. Only "env:NAME" is currently supported.
+	SecretRef string `yaml:"secret_ref"`
+}
+
+// ResolveSecret returns the repository's webhook secret, reading it from
+// SecretRef if Secret itself isn't set.
+func (r *Repository) ResolveSecret() (string, error) {
+	if r.Secret != "" {
+		return r.Secret, nil
+	}
+	if r.SecretRef == "" {
+		return "", nil
+	}
+	name, ok := strings.CutPrefix(r.SecretRef, "env:")
+	if !ok {
+		return "", fmt.Errorf("unsupported secret_ref %q, want env:NAME", r.SecretRef)
+	}
+	secret, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("secret_ref %q: environment variable %s is not set", r.SecretRef, name)
+	}
+	return secret, nil
+}
+
+// Policy declares which incoming tags should trigger an update, and which
+// one to pick when several qualify.
+type Policy struct {
+	// Semver, if set, restricts tags to those that both parse as semver
+	// and satisfy this constraint (e.g. ">=1.2.0 <2.0.0"), picking the
+	// highest matching version.
+	Semver string `yaml:"semver"`
+	// Regex, if set, restricts tags to those matching this pattern, e.g.
+	// "^v\\d+\\.\\d+\\.\\d+$".
+	Regex string `yaml:"regex"`
+	// Alphabetical picks the highest ("desc", the default when a policy
+	// is set but Alphabetical is unset) or lowest ("asc") tag by string
+	// ordering. Ignored when Semver is set.
+	Alphabetical string `yaml:"alphabetical"`
+	// LatestN, if set, restricts tags to the last N reported by the hook
+	// that pass Regex, before Semver or Alphabetical picks one of them.
+	LatestN int `yaml:"latest_n"`
+}
+
+// Update describes a single file edit to make in response to an image push.
+type Update struct {
+	// FilePath is the path, within the repository's SourceRepo, of the
+	// file to update.
+	FilePath string `yaml:"file_path"`
+	// UpdateKey locates the image reference to rewrite. Its meaning depends
+	// on Format: a dotted path for yaml and json (e.g. "test.image"), the
+	// parent map for helm, the images entry's name for kustomize, and the
+	// base image name of the FROM line for dockerfile.
+	UpdateKey string `yaml:"update_key"`
+	// ImageMatch, if set, is a regular expression the hook's image
+	// reference must match for this update to apply. Leave empty to apply
+	// unconditionally; useful when a repository has multiple Updates
+	// covering different images.
+	ImageMatch string `yaml:"image_match"`
+	// TagTemplate is a Go template, evaluated with ".Tag", ".Digest",
+	// ".Repository" and ".Owner", used to render the new image reference.
+	// Defaults to "{{.Repository}}:{{.Tag}}".
+	TagTemplate string `yaml:"tag_template"`
+	// Format selects the rewriter used to apply the update: yaml, json,
+	// kustomize, helm or dockerfile. Left empty, it's inferred from
+	// FilePath (see rewriter.ForFormat); helm must always be set
+	// explicitly, since a Helm values fragment is indistinguishable from
+	// any other YAML file by name alone.
+	Format string `yaml:"format"`
+}
+
+// Targets returns the file updates this repository should apply, folding
+// the legacy FilePath/UpdateKey fields into a single Update when Updates
+// hasn't been set.
+func (r *Repository) Targets() []Update {
+	if len(r.Updates) > 0 {
+		return r.Updates
+	}
+	return []Update{{FilePath: r.FilePath, UpdateKey: r.UpdateKey}}
+}
+
+// SecretsByRepo resolves every configured repository's webhook secret,
+// keyed by Repository.Name, skipping repositories that don't have one. The
+// caller must verify an incoming webhook against the secret of the specific
+// repository it claims to update, not just any configured secret: a flat
+// list would let the holder of one repository's secret forge updates to a
+// different one.
+func (c *RepoConfiguration) SecretsByRepo() (map[string]string, error) {
+	secrets := map[string]string{}
+	for _, r := range c.Repositories {
+		secret, err := r.ResolveSecret()
+		if err != nil {
+			return nil, fmt.Errorf("repository %s: %w", r.Name, err)
+		}
+		if secret != "" {
+			secrets[r.Name] = secret
+		}
+	}
+	return secrets, nil
+}
+
+// Parse reads and validates a repository configuration file.
+func Parse(in io.Reader) (*RepoConfiguration, error) {
+	var config RepoConfiguration
+	if err := yaml.NewDecoder(in).Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	for _, r := range config.Repositories {
+		if r.SourceBranch == "" {
+			r.SourceBranch = "master"
+		}
+	}
+	return &config, nil
+}
@@ -0,0 +1,85 @@
+// Package handler wires a hooks.PushEventParser and an updater together
+// into an http.Handler that can be mounted directly on a ServeMux.
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+	"github.com/gitops-tools/image-hooks/pkg/metrics"
+)
+
+// Updater applies a decoded hook to the configured repositories.
+type Updater interface {
+	UpdateFromHook(ctx context.Context, hook hooks.Hook) error
+}
+
+// Handler parses incoming webhook requests and forwards them to an Updater.
+type Handler struct {
+	log        *zap.SugaredLogger
+	updater    Updater
+	parser     hooks.PushEventParser
+	parserName string
+	metrics    *metrics.Metrics
+}
+
+// New creates a Handler for parser, identified in logs and metrics as
+// parserName (the --parser flag's value). Its metrics are registered with
+// a private registry by default; call SetMetrics to have them served from
+// the process's /metrics endpoint.
+func New(log *zap.SugaredLogger, updater Updater, parser hooks.PushEventParser, parserName string) *Handler {
+	return &Handler{
+		log:        log,
+		updater:    updater,
+		parser:     parser,
+		parserName: parserName,
+		metrics:    metrics.New(prometheus.NewRegistry()),
+	}
+}
+
+// SetMetrics replaces the Handler's metrics, e.g. with one registered
+// against the process's default Prometheus registry.
+func (h *Handler) SetMetrics(m *metrics.Metrics) {
+	h.metrics = m
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	hook, err := h.parser(r)
+	if err != nil {
+		h.log.Warnw("failed to parse incoming webhook", "parser", h.parserName, "error", err)
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	repo := hook.GetRepository()
+	err = h.updater.UpdateFromHook(r.Context(), hook)
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	h.metrics.EventsTotal.WithLabelValues(h.parserName, repo, result).Inc()
+	h.log.Infow("processed image push event",
+		"parser", h.parserName,
+		"repo", repo,
+		"docker_url", hook.GetDockerURL(),
+		"updated_tags", hook.GetUpdatedTags(),
+		"duration", time.Since(start),
+		"result", result,
+	)
+
+	if err != nil {
+		h.log.Errorw("failed to process image update", "parser", h.parserName, "repo", repo, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
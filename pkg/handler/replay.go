@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// replayCacheSize bounds memory use regardless of how busy the endpoint is;
+// a delivery ID older than the window is evicted long before this anyway.
+const replayCacheSize = 10000
+
+type replayEntry struct {
+	id   string
+	seen time.Time
+}
+
+// ReplayCache rejects a webhook delivery that's been seen before within a
+// configurable window, using an LRU of recent delivery IDs so memory use
+// stays bounded under sustained traffic.
+type ReplayCache struct {
+	mu      sync.Mutex
+	window  time.Duration
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+// NewReplayCache creates a ReplayCache that considers a delivery a replay
+// if it's seen again within window.
+func NewReplayCache(window time.Duration) *ReplayCache {
+	return &ReplayCache{
+		window:  window,
+		ll:      list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Seen records id and reports whether it was already seen within the
+// window.
+func (c *ReplayCache) Seen(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictExpired()
+
+	if el, ok := c.entries[id]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*replayEntry).seen = time.Now()
+		return true
+	}
+
+	el := c.ll.PushFront(&replayEntry{id: id, seen: time.Now()})
+	c.entries[id] = el
+	if c.ll.Len() > replayCacheSize {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.entries, oldest.Value.(*replayEntry).id)
+	}
+	return false
+}
+
+func (c *ReplayCache) evictExpired() {
+	cutoff := time.Now().Add(-c.window)
+	for {
+		oldest := c.ll.Back()
+		if oldest == nil || oldest.Value.(*replayEntry).seen.After(cutoff) {
+			return
+		}
+		c.ll.Remove(oldest)
+		delete(c.entries, oldest.Value.(*replayEntry).id)
+	}
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplayCacheRejectsRepeatedDelivery(t *testing.T) {
+	c := NewReplayCache(time.Minute)
+
+	if c.Seen("a") {
+		t.Fatal("first sighting of \"a\" should not be a replay")
+	}
+	if !c.Seen("a") {
+		t.Fatal("second sighting of \"a\" should be a replay")
+	}
+}
+
+func TestReplayCacheForgetsAfterWindow(t *testing.T) {
+	c := NewReplayCache(time.Millisecond)
+
+	c.Seen("a")
+	time.Sleep(5 * time.Millisecond)
+
+	if c.Seen("a") {
+		t.Fatal("delivery outside the window should not be treated as a replay")
+	}
+}
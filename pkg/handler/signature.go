@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+)
+
+// SignatureVerifier checks that an incoming webhook request was really sent
+// by the registry it claims to be from, given one of the repository secrets
+// it might have been signed with.
+type SignatureVerifier func(r *http.Request, body []byte, secret string) error
+
+// Verifiers maps a --parser name to the verification scheme that registry
+// uses. Registries that authenticate with a bearer token rather than
+// signing the body (Quay, Docker Hub, Harbor) are verified the same way.
+var Verifiers = map[string]SignatureVerifier{
+	"quay":        verifyBearerToken,
+	"docker":      verifyBearerToken,
+	"harbor":      verifyBearerToken,
+	"artifactory": verifyBearerToken,
+	"ghcr":        verifyGitHubSignature,
+}
+
+func verifyBearerToken(r *http.Request, _ []byte, secret string) error {
+	got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if got == "" || !hmac.Equal([]byte(got), []byte(secret)) {
+		return fmt.Errorf("missing or invalid bearer token")
+	}
+	return nil
+}
+
+func verifyGitHubSignature(r *http.Request, body []byte, secret string) error {
+	sig := r.Header.Get("X-Hub-Signature-256")
+	if sig == "" {
+		return fmt.Errorf("missing X-Hub-Signature-256 header")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// deliveryID returns the value webhook providers use to identify a single
+// delivery attempt, for replay detection.
+func deliveryID(r *http.Request) string {
+	for _, header := range []string{"X-GitHub-Delivery", "X-Request-Id", "X-Hub-Delivery"} {
+		if id := r.Header.Get(header); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// RequireSignature wraps next so that a request must verify, using verify,
+// against the webhook secret of the specific repository the decoded hook
+// targets (secrets, keyed by Repository.Name) before it's allowed through.
+// Verifying against any configured repository's secret, rather than the one
+// the payload claims to update, would let the holder of one repository's
+// secret forge an update to a different, unrelated repository, so parser is
+// used here to decode the hook and look up its repository before the body
+// is accepted. If replay is non-nil, a delivery ID seen before within its
+// window is also rejected.
+func RequireSignature(next http.Handler, verify SignatureVerifier, parser hooks.PushEventParser, secrets map[string]string, replay *ReplayCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hook, err := parser(r)
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		secret, ok := secrets[hook.GetRepository()]
+		if !ok || verify(r, body, secret) != nil {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		if replay != nil {
+			if id := deliveryID(r); id != "" && replay.Seen(id) {
+				http.Error(w, "replayed delivery", http.StatusConflict)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks/quay"
+)
+
+func newSignedRequest(t *testing.T, repo, token string) *http.Request {
+	t.Helper()
+	body := `{"repository":"` + repo + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestRequireSignatureAcceptsMatchingRepoSecret(t *testing.T) {
+	secrets := map[string]string{"org/repo-a": "secret-a", "org/repo-b": "secret-b"}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := RequireSignature(next, verifyBearerToken, quay.Parse, secrets, nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newSignedRequest(t, "org/repo-a", "secret-a"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRequireSignatureRejectsAnotherRepoSecret(t *testing.T) {
+	secrets := map[string]string{"org/repo-a": "secret-a", "org/repo-b": "secret-b"}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := RequireSignature(next, verifyBearerToken, quay.Parse, secrets, nil)
+
+	// Signed correctly for repo-a, but the payload claims to update repo-b:
+	// repo-a's secret must not authorize an update to repo-b.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newSignedRequest(t, "org/repo-b", "secret-a"))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireSignatureRejectsUnknownRepo(t *testing.T) {
+	secrets := map[string]string{"org/repo-a": "secret-a"}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := RequireSignature(next, verifyBearerToken, quay.Parse, secrets, nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newSignedRequest(t, "org/unconfigured-repo", "secret-a"))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,70 @@
+// Package artifactory parses JFrog Artifactory's generic "docker" domain
+// webhooks, used to cover self-hosted OCI registries that don't have a
+// dedicated parser.
+package artifactory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+)
+
+// dockerDomain is the only Artifactory webhook domain this parser handles.
+const dockerDomain = "docker"
+
+// Event is the payload Artifactory sends for repository webhooks. Only the
+// "docker" domain (pushed/promoted images) is relevant here.
+type Event struct {
+	Domain    string `json:"domain"`
+	EventType string `json:"event_type"`
+	Data      struct {
+		RepoKey string `json:"repo_key"`
+		Path    string `json:"path"`
+		Name    string `json:"name"`
+	} `json:"data"`
+}
+
+// GetRepository implements hooks.Hook.
+func (e *Event) GetRepository() string {
+	return fmt.Sprintf("%s/%s", e.Data.RepoKey, imageName(e.Data.Path))
+}
+
+// GetDockerURL implements hooks.Hook.
+func (e *Event) GetDockerURL() string {
+	return fmt.Sprintf("%s/%s", e.Data.RepoKey, imageName(e.Data.Path))
+}
+
+// GetUpdatedTags implements hooks.Hook.
+func (e *Event) GetUpdatedTags() []string {
+	parts := strings.Split(strings.Trim(e.Data.Path, "/"), "/")
+	if len(parts) < 2 {
+		return nil
+	}
+	return []string{parts[len(parts)-1]}
+}
+
+// imageName strips the trailing tag segment from an Artifactory docker repo
+// path, e.g. "myimage/1.0" -> "myimage".
+func imageName(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		return path
+	}
+	return strings.Join(parts[:len(parts)-1], "/")
+}
+
+// Parse decodes an Artifactory webhook body into a hooks.Hook.
+func Parse(r *http.Request) (hooks.Hook, error) {
+	defer r.Body.Close()
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		return nil, fmt.Errorf("failed to parse artifactory event: %w", err)
+	}
+	if event.Domain != dockerDomain {
+		return nil, fmt.Errorf("ignoring artifactory event for domain %q", event.Domain)
+	}
+	return &event, nil
+}
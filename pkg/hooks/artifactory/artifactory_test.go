@@ -0,0 +1,44 @@
+package artifactory
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	body := `{"domain":"docker","event_type":"pushed","data":{"repo_key":"docker-local","path":"myimage/1.0","name":"manifest.json"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	hook, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hook.GetRepository(), "docker-local/myimage"; got != want {
+		t.Fatalf("got repository %q, want %q", got, want)
+	}
+	if got, want := hook.GetDockerURL(), "docker-local/myimage"; got != want {
+		t.Fatalf("got docker url %q, want %q", got, want)
+	}
+	if got, want := hook.GetUpdatedTags(), []string{"1.0"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got tags %v, want %v", got, want)
+	}
+}
+
+func TestParseRejectsNonDockerDomain(t *testing.T) {
+	body := `{"domain":"generic","event_type":"deployed","data":{"repo_key":"generic-local","path":"some/file.zip"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for a non-docker domain")
+	}
+}
+
+func TestParseRejectsInvalidJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`not json`))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,66 @@
+// Package auto lets a single endpoint serve webhooks from several
+// registries by inspecting headers and, failing that, a few well-known body
+// fields, then delegating to that registry's own parser.
+package auto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/artifactory"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/docker"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/ghcr"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/harbor"
+	"github.com/gitops-tools/image-hooks/pkg/hooks/quay"
+)
+
+// probe is decoded speculatively to sniff which registry sent a payload that
+// carries no identifying header.
+type probe struct {
+	Type        string          `json:"type"`
+	PushData    json.RawMessage `json:"push_data"`
+	UpdatedTags json.RawMessage `json:"updated_tags"`
+}
+
+// Parse detects which registry sent an incoming webhook and delegates to
+// that registry's parser. Detection prefers headers (cheap, unambiguous)
+// and falls back to sniffing the JSON body.
+func Parse(r *http.Request) (hooks.Hook, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+	r.Body.Close()
+
+	if r.Header.Get("X-GitHub-Event") == "package" {
+		return replay(body, r, ghcr.Parse)
+	}
+	if r.Header.Get("X-Jfrog-Event-Id") != "" {
+		return replay(body, r, artifactory.Parse)
+	}
+
+	var p probe
+	if err := json.Unmarshal(body, &p); err == nil {
+		switch {
+		case p.Type == "PUSH_ARTIFACT":
+			return replay(body, r, harbor.Parse)
+		case p.PushData != nil:
+			return replay(body, r, docker.Parse)
+		case p.UpdatedTags != nil:
+			return replay(body, r, quay.Parse)
+		}
+	}
+
+	return nil, fmt.Errorf("auto: unable to determine registry for incoming webhook")
+}
+
+// replay resets the request body so the delegate parser can read it from
+// the start, then invokes it.
+func replay(body []byte, r *http.Request, parse hooks.PushEventParser) (hooks.Hook, error) {
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return parse(r)
+}
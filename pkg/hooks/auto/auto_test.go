@@ -0,0 +1,69 @@
+package auto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseDetectsGHCRByHeader(t *testing.T) {
+	body := `{"action":"published","package":{"package_type":"container","name":"img","package_version":{"container_metadata":{"tag":{"name":"v1.0.0"}}}},"repository":{"full_name":"acme/widget"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "package")
+
+	hook, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hook.GetRepository(), "acme/widget"; got != want {
+		t.Fatalf("got repository %q, want %q", got, want)
+	}
+}
+
+func TestParseDetectsHarborByBodyType(t *testing.T) {
+	body := `{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"tag":"latest","resource_url":"harbor.example.com/library/photon:latest"}],"repository":{"namespace":"library","name":"photon"}}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	hook, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hook.GetRepository(), "library/photon"; got != want {
+		t.Fatalf("got repository %q, want %q", got, want)
+	}
+}
+
+func TestParseDetectsDockerHubByPushData(t *testing.T) {
+	body := `{"push_data":{"tag":"latest"},"repository":{"repo_name":"acme/widget"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	hook, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hook.GetRepository(), "acme/widget"; got != want {
+		t.Fatalf("got repository %q, want %q", got, want)
+	}
+}
+
+func TestParseDetectsQuayByUpdatedTags(t *testing.T) {
+	body := `{"repository":"acme/widget","docker_url":"quay.io/acme/widget","updated_tags":["production"]}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	hook, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hook.GetRepository(), "acme/widget"; got != want {
+		t.Fatalf("got repository %q, want %q", got, want)
+	}
+}
+
+func TestParseReturnsErrorForUnknownPayload(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"hello":"world"}`))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for an unrecognised payload")
+	}
+}
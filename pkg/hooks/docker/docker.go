@@ -0,0 +1,43 @@
+// Package docker parses Docker Hub's repository webhooks.
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+)
+
+// PushHook is the payload Docker Hub sends when an image is pushed to a
+// repository that has a webhook configured.
+type PushHook struct {
+	CallbackURL string `json:"callback_url"`
+	PushData    struct {
+		Tag      string `json:"tag"`
+		Pusher   string `json:"pusher"`
+		PushedAt int64  `json:"pushed_at"`
+	} `json:"push_data"`
+	Repository struct {
+		RepoName string `json:"repo_name"`
+	} `json:"repository"`
+}
+
+// GetRepository implements hooks.Hook.
+func (h *PushHook) GetRepository() string { return h.Repository.RepoName }
+
+// GetDockerURL implements hooks.Hook.
+func (h *PushHook) GetDockerURL() string { return h.Repository.RepoName }
+
+// GetUpdatedTags implements hooks.Hook.
+func (h *PushHook) GetUpdatedTags() []string { return []string{h.PushData.Tag} }
+
+// Parse decodes a Docker Hub webhook body into a hooks.Hook.
+func Parse(r *http.Request) (hooks.Hook, error) {
+	defer r.Body.Close()
+	var hook PushHook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		return nil, fmt.Errorf("failed to parse docker hub push event: %w", err)
+	}
+	return &hook, nil
+}
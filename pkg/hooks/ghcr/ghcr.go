@@ -0,0 +1,75 @@
+// Package ghcr parses GitHub Container Registry "package" webhooks.
+package ghcr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+)
+
+// PackageEvent is the payload GitHub sends for the "package" event when a
+// container image version is published to GHCR.
+//
+// See: https://docs.github.com/en/webhooks/webhook-events-and-payloads#package
+type PackageEvent struct {
+	Action  string `json:"action"`
+	Package struct {
+		Name           string `json:"name"`
+		PackageType    string `json:"package_type"`
+		PackageVersion struct {
+			Version           string `json:"version"`
+			ContainerMetadata struct {
+				Tag struct {
+					Name string `json:"name"`
+				} `json:"tag"`
+			} `json:"container_metadata"`
+		} `json:"package_version"`
+	} `json:"package"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// GetRepository implements hooks.Hook.
+func (e *PackageEvent) GetRepository() string { return e.Repository.FullName }
+
+// GetDockerURL implements hooks.Hook. GHCR image references are
+// "ghcr.io/<owner>/<package-name>", not "ghcr.io/<owner>/<repo>/<package>",
+// so only the owner segment of the repository's full name is used.
+func (e *PackageEvent) GetDockerURL() string {
+	owner, _, _ := strings.Cut(e.Repository.FullName, "/")
+	return fmt.Sprintf("ghcr.io/%s/%s", owner, e.Package.Name)
+}
+
+// GetDigest implements hooks.DigestHook. GHCR reports the package version's
+// content-addressable identifier as its "version".
+func (e *PackageEvent) GetDigest() string { return e.Package.PackageVersion.Version }
+
+// GetUpdatedTags implements hooks.Hook.
+func (e *PackageEvent) GetUpdatedTags() []string {
+	if tag := e.Package.PackageVersion.ContainerMetadata.Tag.Name; tag != "" {
+		return []string{tag}
+	}
+	return nil
+}
+
+// Parse decodes a GHCR "package" webhook body into a hooks.Hook. It only
+// acts on "published" and "updated" events for container packages; anything
+// else is reported as an error so the caller can log and discard it.
+func Parse(r *http.Request) (hooks.Hook, error) {
+	defer r.Body.Close()
+	var event PackageEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		return nil, fmt.Errorf("failed to parse ghcr package event: %w", err)
+	}
+	if event.Package.PackageType != "container" {
+		return nil, fmt.Errorf("ignoring ghcr package event for non-container package type %q", event.Package.PackageType)
+	}
+	if event.Action != "published" && event.Action != "updated" {
+		return nil, fmt.Errorf("ignoring ghcr package event with action %q", event.Action)
+	}
+	return &event, nil
+}
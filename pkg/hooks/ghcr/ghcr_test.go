@@ -0,0 +1,65 @@
+package ghcr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	body := `{"action":"published","package":{"package_type":"container","name":"widget","package_version":{"version":"sha256:abc","container_metadata":{"tag":{"name":"v1.0.0"}}}},"repository":{"full_name":"acme/widget"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	hook, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hook.GetRepository(), "acme/widget"; got != want {
+		t.Fatalf("got repository %q, want %q", got, want)
+	}
+	if got, want := hook.GetDockerURL(), "ghcr.io/acme/widget"; got != want {
+		t.Fatalf("got docker url %q, want %q", got, want)
+	}
+	if got, want := hook.GetUpdatedTags(), []string{"v1.0.0"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got tags %v, want %v", got, want)
+	}
+	if got, want := hook.(interface{ GetDigest() string }).GetDigest(), "sha256:abc"; got != want {
+		t.Fatalf("got digest %q, want %q", got, want)
+	}
+}
+
+func TestParseAcceptsUpdatedAction(t *testing.T) {
+	body := `{"action":"updated","package":{"package_type":"container"},"repository":{"full_name":"acme/widget"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	if _, err := Parse(req); err != nil {
+		t.Fatalf("expected updated action to be accepted like published, got %s", err)
+	}
+}
+
+func TestParseRejectsNonContainerPackageType(t *testing.T) {
+	body := `{"action":"published","package":{"package_type":"npm"},"repository":{"full_name":"acme/widget"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for a non-container package type")
+	}
+}
+
+func TestParseRejectsUnknownAction(t *testing.T) {
+	body := `{"action":"deleted","package":{"package_type":"container"},"repository":{"full_name":"acme/widget"}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for an unhandled action")
+	}
+}
+
+func TestParseRejectsInvalidJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`not json`))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
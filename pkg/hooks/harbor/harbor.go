@@ -0,0 +1,77 @@
+// Package harbor parses Harbor's "PUSH_ARTIFACT" webhooks.
+package harbor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+)
+
+// pushArtifactType is the Harbor event Type this parser handles.
+const pushArtifactType = "PUSH_ARTIFACT"
+
+// Event is the payload Harbor sends for its webhook events. Only the fields
+// needed to drive an image update are decoded.
+type Event struct {
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Tag         string `json:"tag"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+		Repository struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"repository"`
+	} `json:"event_data"`
+}
+
+// GetRepository implements hooks.Hook.
+func (e *Event) GetRepository() string {
+	return fmt.Sprintf("%s/%s", e.EventData.Repository.Namespace, e.EventData.Repository.Name)
+}
+
+// GetDockerURL implements hooks.Hook. Harbor's resource_url carries the
+// pushed tag (or digest), which is stripped here.
+func (e *Event) GetDockerURL() string {
+	if len(e.EventData.Resources) == 0 {
+		return ""
+	}
+	ref := e.EventData.Resources[0].ResourceURL
+	if idx := strings.LastIndex(ref, "@"); idx != -1 {
+		return ref[:idx]
+	}
+	if idx := strings.LastIndex(ref, ":"); idx > strings.LastIndex(ref, "/") {
+		return ref[:idx]
+	}
+	return ref
+}
+
+// GetUpdatedTags implements hooks.Hook.
+func (e *Event) GetUpdatedTags() []string {
+	tags := make([]string, 0, len(e.EventData.Resources))
+	for _, r := range e.EventData.Resources {
+		if r.Tag != "" {
+			tags = append(tags, r.Tag)
+		}
+	}
+	return tags
+}
+
+// Parse decodes a Harbor webhook body into a hooks.Hook. Harbor signals the
+// event kind in the body's `type` field rather than an HTTP header, so
+// anything other than a push-artifact event is rejected here.
+func Parse(r *http.Request) (hooks.Hook, error) {
+	defer r.Body.Close()
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		return nil, fmt.Errorf("failed to parse harbor event: %w", err)
+	}
+	if event.Type != pushArtifactType {
+		return nil, fmt.Errorf("ignoring harbor event of type %q", event.Type)
+	}
+	return &event, nil
+}
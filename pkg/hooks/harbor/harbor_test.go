@@ -0,0 +1,44 @@
+package harbor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	body := `{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"tag":"latest","resource_url":"harbor.example.com/library/photon:latest"}],"repository":{"namespace":"library","name":"photon"}}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	hook, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hook.GetRepository(), "library/photon"; got != want {
+		t.Fatalf("got repository %q, want %q", got, want)
+	}
+	if got, want := hook.GetDockerURL(), "harbor.example.com/library/photon"; got != want {
+		t.Fatalf("got docker url %q, want %q", got, want)
+	}
+	if got, want := hook.GetUpdatedTags(), []string{"latest"}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got tags %v, want %v", got, want)
+	}
+}
+
+func TestParseRejectsNonPushArtifactType(t *testing.T) {
+	body := `{"type":"DELETE_ARTIFACT","event_data":{"repository":{"namespace":"library","name":"photon"}}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for a non-push-artifact event type")
+	}
+}
+
+func TestParseRejectsInvalidJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`not json`))
+
+	if _, err := Parse(req); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
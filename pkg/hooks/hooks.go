@@ -0,0 +1,28 @@
+// Package hooks defines the common shape that registry push-webhook parsers
+// normalize their provider-specific payloads into, so that the rest of the
+// pipeline (handler, updater) never needs to know which registry a given
+// request came from.
+package hooks
+
+import "net/http"
+
+// Hook is implemented by every registry's decoded push-event payload.
+type Hook interface {
+	// GetRepository returns the logical name used to match a hook against a
+	// configured repository, e.g. "mynamespace/repository".
+	GetRepository() string
+	// GetDockerURL returns the pullable image reference, without a tag.
+	GetDockerURL() string
+	// GetUpdatedTags returns the tags that were pushed in this event.
+	GetUpdatedTags() []string
+}
+
+// PushEventParser decodes an incoming webhook request into a Hook.
+type PushEventParser func(r *http.Request) (Hook, error)
+
+// DigestHook is implemented by hooks whose payload also reports the content
+// digest of the pushed image, for registries where the webhook includes it.
+type DigestHook interface {
+	Hook
+	GetDigest() string
+}
@@ -0,0 +1,41 @@
+// Package quay parses Quay.io's "repository push" notification webhooks.
+package quay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+)
+
+// RepositoryPushHook is the payload Quay.io sends when a repository
+// notification is configured with the "webhook POST" event and a new tag is
+// pushed.
+type RepositoryPushHook struct {
+	Name        string   `json:"name"`
+	Repository  string   `json:"repository"`
+	Namespace   string   `json:"namespace"`
+	DockerURL   string   `json:"docker_url"`
+	Homepage    string   `json:"homepage"`
+	UpdatedTags []string `json:"updated_tags"`
+}
+
+// GetRepository implements hooks.Hook.
+func (h *RepositoryPushHook) GetRepository() string { return h.Repository }
+
+// GetDockerURL implements hooks.Hook.
+func (h *RepositoryPushHook) GetDockerURL() string { return h.DockerURL }
+
+// GetUpdatedTags implements hooks.Hook.
+func (h *RepositoryPushHook) GetUpdatedTags() []string { return h.UpdatedTags }
+
+// Parse decodes a Quay.io repository-push webhook body into a hooks.Hook.
+func Parse(r *http.Request) (hooks.Hook, error) {
+	defer r.Body.Close()
+	var hook RepositoryPushHook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		return nil, fmt.Errorf("failed to parse quay push event: %w", err)
+	}
+	return &hook, nil
+}
@@ -0,0 +1,46 @@
+// Package metrics defines the Prometheus instrumentation shared by the
+// updater and handler packages.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics holds every collector image-hooks exposes on /metrics.
+type Metrics struct {
+	// EventsTotal counts processed push events, by parser, repository and
+	// outcome ("ok" or "error").
+	EventsTotal *prometheus.CounterVec
+	// UpdateDuration observes how long it takes to apply an update, from
+	// hook receipt to the final commit or pull request, by repository.
+	UpdateDuration *prometheus.HistogramVec
+	// PullRequestsOpen counts pull requests image-hooks has opened. It
+	// only increases: image-hooks doesn't currently watch for merges or
+	// closures.
+	PullRequestsOpen prometheus.Gauge
+	// SCMAPIErrorsTotal counts errors returned by the SCM API, by
+	// operation (e.g. "get_file", "create_branch").
+	SCMAPIErrorsTotal *prometheus.CounterVec
+}
+
+// New creates a Metrics and registers its collectors with reg.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		EventsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "image_hooks_events_total",
+			Help: "Total number of push events processed, by parser, repository and outcome.",
+		}, []string{"parser", "repo", "result"}),
+		UpdateDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "image_hooks_update_duration_seconds",
+			Help: "Time taken to apply an image update, from hook receipt to commit or pull request.",
+		}, []string{"repo"}),
+		PullRequestsOpen: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "image_hooks_pull_requests_open",
+			Help: "Number of update pull requests image-hooks has opened.",
+		}),
+		SCMAPIErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "image_hooks_scm_api_errors_total",
+			Help: "Total number of errors returned by the SCM API, by operation.",
+		}, []string{"op"}),
+	}
+	reg.MustRegister(m.EventsTotal, m.UpdateDuration, m.PullRequestsOpen, m.SCMAPIErrorsTotal)
+	return m
+}
@@ -0,0 +1,49 @@
+package rewriter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dockerfile rewrites the image reference of a matching FROM line. key
+// matches against the line's current image name (without tag/digest),
+// which lets a multi-stage Dockerfile target one base image without
+// disturbing the others.
+type Dockerfile struct{}
+
+func (Dockerfile) Rewrite(content []byte, key, newImage string) ([]byte, error) {
+	lines := strings.Split(string(content), "\n")
+	found := false
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		if key != "" && imageNameOf(fields[1]) != key {
+			continue
+		}
+		newLine := "FROM " + newImage
+		if len(fields) > 2 {
+			newLine += " " + strings.Join(fields[2:], " ")
+		}
+		lines[i] = newLine
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("no FROM line matching %q found", key)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// imageNameOf strips the tag or digest from a FROM line's image reference.
+func imageNameOf(ref string) string {
+	if idx := strings.LastIndex(ref, "@"); idx != -1 {
+		return ref[:idx]
+	}
+	lastColon := strings.LastIndex(ref, ":")
+	lastSlash := strings.LastIndex(ref, "/")
+	if lastColon > lastSlash {
+		return ref[:lastColon]
+	}
+	return ref
+}
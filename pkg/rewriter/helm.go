@@ -0,0 +1,77 @@
+package rewriter
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Helm rewrites a Helm values fragment shaped like:
+//
+//	image:
+//	  repository: quay.io/org/app
+//	  tag: v1.2.3
+//
+// key names the parent map (e.g. "image"); newImage is split into
+// repository and tag by splitImageRef. When newImage has no tag (e.g. a
+// digest-pinned reference), only repository is set and tag is left as is.
+type Helm struct{}
+
+func (Helm) Rewrite(content []byte, key, newImage string) ([]byte, error) {
+	repository, tag := splitImageRef(newImage)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+	root, err := mappingRoot(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.Split(key, ".")
+	imageNode, err := nestedMapping(root, path)
+	if err != nil {
+		return nil, err
+	}
+	setMapScalar(imageNode, "repository", repository)
+	if tag != "" {
+		setMapScalar(imageNode, "tag", tag)
+	}
+
+	return encodeYAML(&doc)
+}
+
+// nestedMapping walks path through nested mapping nodes, creating missing
+// intermediate mappings as it goes.
+func nestedMapping(root *yaml.Node, path []string) (*yaml.Node, error) {
+	node := root
+	for _, key := range path {
+		v := mapValue(node, key)
+		if v == nil {
+			v = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+		}
+		if v.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("key %q is not a mapping", key)
+		}
+		node = v
+	}
+	return node, nil
+}
+
+// splitImageRef splits "repo:tag" into its repository and tag parts. A
+// digest-pinned reference ("repo@sha256:...") or a bare repository with no
+// tag yields an empty tag.
+func splitImageRef(ref string) (repository, tag string) {
+	if idx := strings.LastIndex(ref, "@"); idx != -1 {
+		return ref[:idx], ""
+	}
+	lastColon := strings.LastIndex(ref, ":")
+	lastSlash := strings.LastIndex(ref, "/")
+	if lastColon > lastSlash {
+		return ref[:lastColon], ref[lastColon+1:]
+	}
+	return ref, ""
+}
@@ -0,0 +1,47 @@
+package rewriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON rewrites the value found at a dotted key path within a JSON
+// document, e.g. "spec.template.image".
+type JSON struct{}
+
+func (JSON) Rewrite(content []byte, key, newImage string) ([]byte, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+	if err := setJSONValue(data, strings.Split(key, "."), newImage); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal json: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func setJSONValue(m map[string]interface{}, path []string, value string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("empty key")
+	}
+	key := path[0]
+	if len(path) == 1 {
+		m[key] = value
+		return nil
+	}
+	next, ok := m[key].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("key %q is not an object", key)
+	}
+	return setJSONValue(next, path[1:], value)
+}
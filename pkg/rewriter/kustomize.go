@@ -0,0 +1,64 @@
+package rewriter
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kustomize rewrites the matching entry of a kustomization.yaml's `images:`
+// block. key is the image's existing `name:` to match; newImage is split
+// into newName/newTag, or newName/digest for a digest-pinned reference.
+type Kustomize struct{}
+
+func (Kustomize) Rewrite(content []byte, key, newImage string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+	root, err := mappingRoot(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	images := mapValue(root, "images")
+	if images == nil || images.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("no images: block found")
+	}
+
+	newName, tag := splitImageRef(newImage)
+	found := false
+	for _, entry := range images.Content {
+		if entry.Kind != yaml.MappingNode {
+			continue
+		}
+		name := mapValue(entry, "name")
+		if name == nil || name.Value != key {
+			continue
+		}
+		found = true
+		setMapScalar(entry, "newName", newName)
+		if digest, ok := digestOf(newImage); ok {
+			setMapScalar(entry, "digest", digest)
+			deleteMapKey(entry, "newTag")
+		} else {
+			setMapScalar(entry, "newTag", tag)
+			deleteMapKey(entry, "digest")
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no images entry named %q found", key)
+	}
+
+	return encodeYAML(&doc)
+}
+
+// digestOf returns the digest portion of a "repo@sha256:..." reference.
+func digestOf(ref string) (string, bool) {
+	for i := len(ref) - 1; i >= 0; i-- {
+		if ref[i] == '@' {
+			return ref[i+1:], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+// Package rewriter implements in-place rewrites of a manifest's image
+// reference, for every file format image-hooks knows how to edit.
+package rewriter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Rewriter rewrites the image reference found at key within content to
+// newImage, returning the updated file. What "key" means is
+// format-specific: a dotted YAML/JSON path, a kustomize image name, or a
+// Dockerfile base-image name.
+type Rewriter interface {
+	Rewrite(content []byte, key, newImage string) ([]byte, error)
+}
+
+// Supported format names, as used in config.Update.Format.
+const (
+	FormatYAML       = "yaml"
+	FormatJSON       = "json"
+	FormatKustomize  = "kustomize"
+	FormatHelm       = "helm"
+	FormatDockerfile = "dockerfile"
+)
+
+// ForFormat returns the Rewriter for format. If format is empty, it's
+// inferred from path's name/extension; this covers yaml, json, kustomize
+// and Dockerfile, but not helm, since a Helm values fragment looks like any
+// other YAML file and must be requested explicitly.
+func ForFormat(format, path string) (Rewriter, error) {
+	if format == "" {
+		format = formatFromPath(path)
+	}
+	switch format {
+	case FormatYAML:
+		return YAML{}, nil
+	case FormatJSON:
+		return JSON{}, nil
+	case FormatKustomize:
+		return Kustomize{}, nil
+	case FormatHelm:
+		return Helm{}, nil
+	case FormatDockerfile:
+		return Dockerfile{}, nil
+	default:
+		return nil, fmt.Errorf("unknown rewriter format %q", format)
+	}
+}
+
+func formatFromPath(path string) string {
+	base := filepath.Base(path)
+	switch {
+	case strings.EqualFold(base, "kustomization.yaml"), strings.EqualFold(base, "kustomization.yml"):
+		return FormatKustomize
+	case strings.EqualFold(base, "Dockerfile"), strings.HasPrefix(base, "Dockerfile."):
+		return FormatDockerfile
+	case strings.HasSuffix(base, ".json"):
+		return FormatJSON
+	default:
+		return FormatYAML
+	}
+}
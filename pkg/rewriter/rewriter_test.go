@@ -0,0 +1,99 @@
+package rewriter
+
+import (
+	"testing"
+)
+
+func TestYAMLRewrite(t *testing.T) {
+	in := "test:\n  image: old-image\n# keep me\n"
+	out, err := YAML{}.Rewrite([]byte(in), "test.image", "new-image:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "test:\n  image: new-image:v1\n# keep me\n"
+	if got := string(out); got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestJSONRewrite(t *testing.T) {
+	in := `{"test":{"image":"old-image"}}`
+	out, err := JSON{}.Rewrite([]byte(in), "test.image", "new-image:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"test\": {\n    \"image\": \"new-image:v1\"\n  }\n}\n"
+	if got := string(out); got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestHelmRewrite(t *testing.T) {
+	in := "image:\n  repository: old/image\n  tag: v0.1.0\n"
+	out, err := Helm{}.Rewrite([]byte(in), "image", "new/image:v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "image:\n  repository: new/image\n  tag: v1.2.3\n"
+	if got := string(out); got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestKustomizeRewrite(t *testing.T) {
+	in := "images:\n  - name: myimage\n    newTag: v0.1.0\n"
+	out, err := Kustomize{}.Rewrite([]byte(in), "myimage", "registry.example.com/myimage:v1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "images:\n  - name: myimage\n    newTag: v1.2.3\n    newName: registry.example.com/myimage\n"
+	if got := string(out); got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestKustomizeRewriteWithDigest(t *testing.T) {
+	in := "images:\n  - name: myimage\n    newTag: v0.1.0\n"
+	out, err := Kustomize{}.Rewrite([]byte(in), "myimage", "registry.example.com/myimage@sha256:abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "images:\n  - name: myimage\n    newName: registry.example.com/myimage\n    digest: sha256:abc123\n"
+	if got := string(out); got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestDockerfileRewrite(t *testing.T) {
+	in := "FROM golang:1.20 AS build\nRUN go build ./...\nFROM alpine:3.18\n"
+	out, err := Dockerfile{}.Rewrite([]byte(in), "golang", "golang:1.21")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "FROM golang:1.21 AS build\nRUN go build ./...\nFROM alpine:3.18\n"
+	if got := string(out); got != want {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestForFormatDefaultsByPath(t *testing.T) {
+	cases := map[string]string{
+		"environments/test/kustomization.yaml": FormatKustomize,
+		"services/app/Dockerfile":              FormatDockerfile,
+		"charts/app/values.json":               FormatJSON,
+		"environments/test/test.yaml":          FormatYAML,
+	}
+	for path, want := range cases {
+		r, err := ForFormat("", path)
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		got := formatFromPath(path)
+		if got != want {
+			t.Fatalf("%s: got format %q, want %q", path, got, want)
+		}
+		if r == nil {
+			t.Fatalf("%s: expected a rewriter", path)
+		}
+	}
+}
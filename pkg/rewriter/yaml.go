@@ -0,0 +1,28 @@
+package rewriter
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML rewrites the scalar found at a dotted key path, e.g. "test.image",
+// preserving comments and formatting by editing the yaml.Node tree rather
+// than round-tripping through a plain map.
+type YAML struct{}
+
+func (YAML) Rewrite(content []byte, key, newImage string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+	root, err := mappingRoot(&doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := setNestedScalar(root, strings.Split(key, "."), newImage); err != nil {
+		return nil, err
+	}
+	return encodeYAML(&doc)
+}
@@ -0,0 +1,97 @@
+package rewriter
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mappingRoot returns the top-level mapping node of a parsed document,
+// unwrapping the document node yaml.Node always produces at the root.
+func mappingRoot(doc *yaml.Node) (*yaml.Node, error) {
+	n := doc
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return nil, fmt.Errorf("empty document")
+		}
+		n = n.Content[0]
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a YAML mapping at the document root")
+	}
+	return n, nil
+}
+
+// mapValue returns the value node for key within a mapping node, or nil if
+// key isn't present.
+func mapValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMapScalar sets key within a mapping node to a string scalar value,
+// adding the key if it isn't already present.
+func setMapScalar(mapping *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			v := mapping.Content[i+1]
+			v.Kind = yaml.ScalarNode
+			v.Tag = "!!str"
+			v.Value = value
+			v.Style = 0
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// deleteMapKey removes key from a mapping node, if present.
+func deleteMapKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// setNestedScalar walks path through nested mapping nodes starting at root,
+// setting the scalar found at the end of it.
+func setNestedScalar(root *yaml.Node, path []string, value string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("empty key")
+	}
+	key := path[0]
+	v := mapValue(root, key)
+	if len(path) == 1 {
+		setMapScalar(root, key, value)
+		return nil
+	}
+	if v == nil || v.Kind != yaml.MappingNode {
+		return fmt.Errorf("key %q is not a mapping", key)
+	}
+	return setNestedScalar(v, path[1:], value)
+}
+
+// encodeYAML marshals a yaml.Node with the indentation the rest of
+// image-hooks writes.
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
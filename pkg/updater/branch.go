@@ -0,0 +1,26 @@
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// deterministicBranchName derives a stable branch name for an update to
+// sourceRepo's targets, so repeated pushes that resolve to the same file and
+// key reuse the same branch (and therefore the same pull request) rather
+// than opening a new one every time. The hash deliberately excludes the
+// rendered image reference: that changes with every new tag pushed for the
+// same tracked image, and keying on it would defeat the whole point of
+// reuse, piling up a new branch/PR per tag instead of one per target.
+func deterministicBranchName(prefix, sourceRepo string, targets []renderedTarget) string {
+	keys := make([]string, 0, len(targets))
+	for _, t := range targets {
+		keys = append(keys, t.path+"|"+t.updateKey)
+	}
+	sort.Strings(keys)
+
+	h := sha256.Sum256([]byte(sourceRepo + "|" + strings.Join(keys, ",")))
+	return prefix + hex.EncodeToString(h[:])[:12]
+}
@@ -0,0 +1,122 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gitops-tools/image-hooks/pkg/client/mock"
+	"github.com/jenkins-x/go-scm/scm"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestUpdaterWithDeterministicBranchStrategyOpensPullRequest(t *testing.T) {
+	testSHA := "980a0d5f19a64b4b30a87d4206aade58726b60e3"
+	m := mock.New(t)
+	m.AddFileContents(testGitHubRepo, testFilePath, "master", []byte("test:\n  image: old-image\n"))
+	m.AddBranchHead(testGitHubRepo, "master", testSHA)
+	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel)).Sugar()
+
+	configs := createConfigs()
+	configs.Repositories[0].BranchStrategy = "deterministic"
+	updater := New(logger, m, configs)
+	hook := createHook()
+
+	if err := updater.UpdateFromHook(context.Background(), hook); err != nil {
+		t.Fatal(err)
+	}
+
+	branch := deterministicBranchName(configs.Repositories[0].BranchGenerateName, testGitHubRepo, []renderedTarget{
+		{path: testFilePath, updateKey: "test.image", image: "quay.io/testorg/repo:production"},
+	})
+	m.AssertBranchCreated(testGitHubRepo, branch, testSHA)
+	m.AssertPullRequestCreated(testGitHubRepo, &scm.PullRequestInput{Head: branch})
+}
+
+func TestUpdaterWithDeterministicBranchStrategyReusesOpenPullRequest(t *testing.T) {
+	testSHA := "980a0d5f19a64b4b30a87d4206aade58726b60e3"
+	branchSHA := "1111111119a64b4b30a87d4206aade58726b60e"
+	m := mock.New(t)
+	m.AddFileContents(testGitHubRepo, testFilePath, "master", []byte("test:\n  image: old-image\n"))
+	m.AddBranchHead(testGitHubRepo, "master", testSHA)
+	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel)).Sugar()
+
+	configs := createConfigs()
+	configs.Repositories[0].BranchStrategy = "deterministic"
+	branch := deterministicBranchName(configs.Repositories[0].BranchGenerateName, testGitHubRepo, []renderedTarget{
+		{path: testFilePath, updateKey: "test.image", image: "quay.io/testorg/repo:production"},
+	})
+	// The branch from a prior run already exists, with its own head
+	// distinct from master's current tip.
+	m.AddBranchHead(testGitHubRepo, branch, branchSHA)
+	m.AddOpenPullRequest(testGitHubRepo, branch, 1, "https://scm.example/pr/1")
+	updater := New(logger, m, configs)
+	hook := createHook()
+
+	if err := updater.UpdateFromHook(context.Background(), hook); err != nil {
+		t.Fatal(err)
+	}
+
+	m.RefuteBranchCreated(testGitHubRepo, branch, testSHA)
+	m.AssertNoPullRequestsCreated()
+	m.AssertPullRequestUpdated(testGitHubRepo, 1, &scm.PullRequestInput{
+		Title: fmt.Sprintf("Image %s updated", testQuayRepo),
+		Body:  "Automated Image Update",
+	})
+
+	want := "test:\n  image: quay.io/testorg/repo:production\n"
+	if s := string(m.GetUpdatedContents(testGitHubRepo, testFilePath, branch)); s != want {
+		t.Fatalf("update failed, got %#v, want %#v", s, want)
+	}
+}
+
+// A busy image gets pushed repeatedly with a new tag each time; the branch
+// name must not change with the tag, or every push would open a new PR
+// instead of reusing the one still open for this target.
+func TestUpdaterWithDeterministicBranchStrategyReusesBranchAcrossDifferentTags(t *testing.T) {
+	testSHA := "980a0d5f19a64b4b30a87d4206aade58726b60e3"
+	m := mock.New(t)
+	m.AddFileContents(testGitHubRepo, testFilePath, "master", []byte("test:\n  image: old-image\n"))
+	m.AddBranchHead(testGitHubRepo, "master", testSHA)
+	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel)).Sugar()
+
+	configs := createConfigs()
+	configs.Repositories[0].BranchStrategy = "deterministic"
+	updater := New(logger, m, configs)
+
+	firstHook := createHook()
+	firstHook.UpdatedTags = []string{"v1"}
+	if err := updater.UpdateFromHook(context.Background(), firstHook); err != nil {
+		t.Fatal(err)
+	}
+
+	branch := deterministicBranchName(configs.Repositories[0].BranchGenerateName, testGitHubRepo, []renderedTarget{
+		{path: testFilePath, updateKey: "test.image"},
+	})
+	m.AssertBranchCreated(testGitHubRepo, branch, testSHA)
+	m.AssertPullRequestCreated(testGitHubRepo, &scm.PullRequestInput{Head: branch})
+
+	// Simulate the PR opened by the first push still being open when the
+	// second push arrives.
+	m.AddOpenPullRequest(testGitHubRepo, branch, 1, "https://scm.example/pr/1")
+
+	secondHook := createHook()
+	secondHook.UpdatedTags = []string{"v2"}
+	if err := updater.UpdateFromHook(context.Background(), secondHook); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := m.PullRequestCount(testGitHubRepo), 1; got != want {
+		t.Fatalf("got %d pull request(s) opened for %s, want %d", got, testGitHubRepo, want)
+	}
+	m.AssertPullRequestUpdated(testGitHubRepo, 1, &scm.PullRequestInput{
+		Title: fmt.Sprintf("Image %s updated", testQuayRepo),
+		Body:  "Automated Image Update",
+	})
+
+	want := "test:\n  image: quay.io/testorg/repo:v2\n"
+	if s := string(m.GetUpdatedContents(testGitHubRepo, testFilePath, branch)); s != want {
+		t.Fatalf("update failed, got %#v, want %#v", s, want)
+	}
+}
@@ -0,0 +1,21 @@
+package updater
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const randomSuffixChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// randomNameGenerator is the production nameGenerator: it appends a short
+// random suffix to the configured prefix so concurrent updates to the same
+// repository don't collide on branch names.
+type randomNameGenerator struct{}
+
+func (randomNameGenerator) PrefixedName(prefix string) string {
+	suffix := make([]byte, 5)
+	for i := range suffix {
+		suffix[i] = randomSuffixChars[rand.Intn(len(randomSuffixChars))]
+	}
+	return fmt.Sprintf("%s%s", prefix, suffix)
+}
@@ -0,0 +1,102 @@
+package updater
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/gitops-tools/image-hooks/pkg/config"
+)
+
+// selectTag picks the single tag, out of those reported by a push hook,
+// that a repository's policy says should actually trigger an update. It
+// returns ok=false (not an error) when the policy rejects every tag, e.g.
+// a hook for "latest" against a repository that only wants semver releases.
+//
+// With no policy configured, behaviour is unchanged from before policies
+// existed: the first reported tag is used.
+func selectTag(policy *config.Policy, tags []string) (string, bool, error) {
+	if policy == nil {
+		if len(tags) == 0 {
+			return "", false, nil
+		}
+		return tags[0], true, nil
+	}
+
+	candidates := tags
+	if policy.Regex != "" {
+		re, err := regexp.Compile(policy.Regex)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid policy regex %q: %w", policy.Regex, err)
+		}
+		candidates = filterTags(candidates, re.MatchString)
+	}
+
+	if policy.LatestN > 0 && len(candidates) > policy.LatestN {
+		candidates = candidates[len(candidates)-policy.LatestN:]
+	}
+
+	if policy.Semver != "" {
+		return selectSemverTag(policy.Semver, candidates)
+	}
+
+	if len(candidates) == 0 {
+		return "", false, nil
+	}
+
+	switch policy.Alphabetical {
+	case "", "desc":
+		sorted := sortedCopy(candidates)
+		return sorted[len(sorted)-1], true, nil
+	case "asc":
+		sorted := sortedCopy(candidates)
+		return sorted[0], true, nil
+	default:
+		return "", false, fmt.Errorf("unknown alphabetical policy direction %q, want asc or desc", policy.Alphabetical)
+	}
+}
+
+func selectSemverTag(constraintStr string, tags []string) (string, bool, error) {
+	constraint, err := semver.NewConstraint(constraintStr)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid policy semver constraint %q: %w", constraintStr, err)
+	}
+
+	var best *semver.Version
+	var bestTag string
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err != nil {
+			continue // not a semver tag, e.g. "latest" or a branch name
+		}
+		if !constraint.Check(v) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best = v
+			bestTag = tag
+		}
+	}
+	if best == nil {
+		return "", false, nil
+	}
+	return bestTag, true, nil
+}
+
+func filterTags(tags []string, keep func(string) bool) []string {
+	var out []string
+	for _, t := range tags {
+		if keep(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func sortedCopy(tags []string) []string {
+	sorted := append([]string{}, tags...)
+	sort.Strings(sorted)
+	return sorted
+}
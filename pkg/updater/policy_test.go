@@ -0,0 +1,73 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/gitops-tools/image-hooks/pkg/config"
+)
+
+func TestSelectTagWithNoPolicy(t *testing.T) {
+	tag, ok, err := selectTag(nil, []string{"latest", "v1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || tag != "latest" {
+		t.Fatalf("got %q, %v, want %q, true", tag, ok, "latest")
+	}
+}
+
+func TestSelectTagWithSemverPolicy(t *testing.T) {
+	policy := &config.Policy{Semver: ">=1.2.0 <2.0.0"}
+	tag, ok, err := selectTag(policy, []string{"latest", "v1.1.0", "v1.5.0", "v2.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || tag != "v1.5.0" {
+		t.Fatalf("got %q, %v, want %q, true", tag, ok, "v1.5.0")
+	}
+}
+
+func TestSelectTagWithSemverPolicyNoMatch(t *testing.T) {
+	policy := &config.Policy{Semver: ">=3.0.0"}
+	_, ok, err := selectTag(policy, []string{"latest", "v1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected no tag to satisfy the policy")
+	}
+}
+
+func TestSelectTagWithRegexPolicy(t *testing.T) {
+	policy := &config.Policy{Regex: `^v\d+\.\d+\.\d+$`}
+	tag, ok, err := selectTag(policy, []string{"latest", "main", "v1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || tag != "v1.2.3" {
+		t.Fatalf("got %q, %v, want %q, true", tag, ok, "v1.2.3")
+	}
+}
+
+func TestSelectTagWithAlphabeticalAscending(t *testing.T) {
+	policy := &config.Policy{Alphabetical: "asc"}
+	tag, ok, err := selectTag(policy, []string{"c", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || tag != "a" {
+		t.Fatalf("got %q, %v, want %q, true", tag, ok, "a")
+	}
+}
+
+func TestSelectTagWithLatestNWindow(t *testing.T) {
+	policy := &config.Policy{LatestN: 2}
+	tag, ok, err := selectTag(policy, []string{"v1", "v2", "v3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only "v2" and "v3" are in the window; alphabetical defaults to desc.
+	if !ok || tag != "v3" {
+		t.Fatalf("got %q, %v, want %q, true", tag, ok, "v3")
+	}
+}
@@ -258,6 +258,66 @@ func TestUpdaterWithNonMasterSourceBranch(t *testing.T) {
 	})
 }
 
+func TestUpdaterWithMultipleUpdateTargets(t *testing.T) {
+	testSHA := "980a0d5f19a64b4b30a87d4206aade58726b60e3"
+	secondFilePath := "environments/test/services/service-b/test.yaml"
+	m := mock.New(t)
+	m.AddFileContents(testGitHubRepo, testFilePath, "master", []byte("test:\n  image: old-image\n"))
+	m.AddFileContents(testGitHubRepo, secondFilePath, "master", []byte("test:\n  image: old-image\n"))
+	m.AddBranchHead(testGitHubRepo, "master", testSHA)
+	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel)).Sugar()
+
+	configs := createConfigs()
+	configs.Repositories[0].FilePath = ""
+	configs.Repositories[0].UpdateKey = ""
+	configs.Repositories[0].Updates = []config.Update{
+		{FilePath: testFilePath, UpdateKey: "test.image"},
+		{FilePath: secondFilePath, UpdateKey: "test.image"},
+	}
+	updater := New(logger, m, configs)
+	updater.nameGenerator = stubNameGenerator{"a"}
+	hook := createHook()
+
+	if err := updater.UpdateFromHook(context.Background(), hook); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "test:\n  image: quay.io/testorg/repo:production\n"
+	if s := string(m.GetUpdatedContents(testGitHubRepo, testFilePath, "test-branch-a")); s != want {
+		t.Fatalf("update failed, got %#v, want %#v", s, want)
+	}
+	if s := string(m.GetUpdatedContents(testGitHubRepo, secondFilePath, "test-branch-a")); s != want {
+		t.Fatalf("update failed, got %#v, want %#v", s, want)
+	}
+}
+
+func TestUpdaterWithTagTemplate(t *testing.T) {
+	testSHA := "980a0d5f19a64b4b30a87d4206aade58726b60e3"
+	m := mock.New(t)
+	m.AddFileContents(testGitHubRepo, testFilePath, "master", []byte("test:\n  image: old-image\n"))
+	m.AddBranchHead(testGitHubRepo, "master", testSHA)
+	logger := zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel)).Sugar()
+
+	configs := createConfigs()
+	configs.Repositories[0].FilePath = ""
+	configs.Repositories[0].UpdateKey = ""
+	configs.Repositories[0].Updates = []config.Update{
+		{FilePath: testFilePath, UpdateKey: "test.image", TagTemplate: "{{.Owner}}/{{.Tag}}"},
+	}
+	updater := New(logger, m, configs)
+	updater.nameGenerator = stubNameGenerator{"a"}
+	hook := createHook()
+
+	if err := updater.UpdateFromHook(context.Background(), hook); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "test:\n  image: testorg/production\n"
+	if s := string(m.GetUpdatedContents(testGitHubRepo, testFilePath, "test-branch-a")); s != want {
+		t.Fatalf("update failed, got %#v, want %#v", s, want)
+	}
+}
+
 func createHook() *quay.RepositoryPushHook {
 	return &quay.RepositoryPushHook{
 		Repository:  testQuayRepo,
@@ -0,0 +1,303 @@
+// Package updater applies an incoming image push hook to the configured
+// source repositories, rewriting the matching file and opening a pull
+// request (or committing directly) with the new image reference.
+package updater
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/gitops-tools/image-hooks/pkg/client"
+	"github.com/gitops-tools/image-hooks/pkg/config"
+	"github.com/gitops-tools/image-hooks/pkg/hooks"
+	"github.com/gitops-tools/image-hooks/pkg/metrics"
+	"github.com/gitops-tools/image-hooks/pkg/rewriter"
+)
+
+// nameGenerator produces a branch name from a prefix. It exists so tests can
+// substitute a deterministic stub for the real, randomised implementation.
+type nameGenerator interface {
+	PrefixedName(prefix string) string
+}
+
+// Updater rewrites configured repositories in response to push hooks.
+type Updater struct {
+	log           *zap.SugaredLogger
+	scm           client.SCM
+	configs       *config.RepoConfiguration
+	nameGenerator nameGenerator
+	metrics       *metrics.Metrics
+}
+
+// New creates an Updater for the given repository configuration. Its
+// metrics are registered with a private registry by default; call
+// SetMetrics to have them served from the process's /metrics endpoint.
+func New(log *zap.SugaredLogger, scmClient client.SCM, configs *config.RepoConfiguration) *Updater {
+	return &Updater{
+		log:           log,
+		scm:           scmClient,
+		configs:       configs,
+		nameGenerator: randomNameGenerator{},
+		metrics:       metrics.New(prometheus.NewRegistry()),
+	}
+}
+
+// SetMetrics replaces the Updater's metrics, e.g. with one registered
+// against the process's default Prometheus registry.
+func (u *Updater) SetMetrics(m *metrics.Metrics) {
+	u.metrics = m
+}
+
+// UpdateFromHook applies hook to every configured repository whose Name
+// matches the hook's reported repository. A hook that matches no configured
+// repository is not an error: most registries fire hooks for images nobody
+// has asked image-hooks to track.
+func (u *Updater) UpdateFromHook(ctx context.Context, hook hooks.Hook) error {
+	for _, repo := range u.configs.Repositories {
+		if repo.Name != hook.GetRepository() {
+			continue
+		}
+		if err := u.updateRepository(ctx, repo, hook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *Updater) updateRepository(ctx context.Context, repo *config.Repository, hook hooks.Hook) (err error) {
+	start := time.Now()
+	defer func() {
+		u.metrics.UpdateDuration.WithLabelValues(repo.Name).Observe(time.Since(start).Seconds())
+	}()
+
+	tag, ok, err := selectTag(repo.Policy, hook.GetUpdatedTags())
+	if err != nil {
+		return fmt.Errorf("failed to evaluate update policy: %w", err)
+	}
+	if !ok {
+		u.log.Infow("no tag reported by the hook satisfied the repository's policy, skipping", "repo", repo.Name, "tags", hook.GetUpdatedTags())
+		return nil
+	}
+
+	targets, err := u.renderTargets(ctx, repo, hook, tag)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		u.log.Infow("no update target matched the pushed image, skipping", "repo", repo.Name)
+		return nil
+	}
+	files := make(map[string]client.FileUpdate, len(targets))
+	for _, target := range targets {
+		files[target.path] = client.FileUpdate{Content: target.content, SHA: target.blobSHA}
+	}
+
+	sha, err := u.scm.GetBranchHead(ctx, repo.SourceRepo, repo.SourceBranch)
+	if err != nil {
+		u.metrics.SCMAPIErrorsTotal.WithLabelValues("get_branch_head").Inc()
+		return err
+	}
+
+	branch := repo.SourceBranch
+	var existingPR *scm.PullRequest
+	if repo.BranchGenerateName != "" {
+		if repo.BranchStrategy == "deterministic" {
+			branch = deterministicBranchName(repo.BranchGenerateName, repo.SourceRepo, targets)
+			prs, err := u.scm.ListPullRequests(ctx, repo.SourceRepo, branch)
+			if err != nil {
+				u.metrics.SCMAPIErrorsTotal.WithLabelValues("list_pull_requests").Inc()
+				return fmt.Errorf("failed to list pull requests: %w", err)
+			}
+			if len(prs) > 0 {
+				existingPR = prs[0]
+				// The open pull request's branch already carries an earlier
+				// edit, so each file is replaced using its blob SHA on that
+				// branch, not the one read from SourceBranch.
+				for path, update := range files {
+					_, branchBlobSHA, err := u.scm.GetFile(ctx, repo.SourceRepo, path, branch)
+					if err != nil {
+						u.metrics.SCMAPIErrorsTotal.WithLabelValues("get_file").Inc()
+						return err
+					}
+					update.SHA = branchBlobSHA
+					files[path] = update
+				}
+			}
+		} else {
+			branch = u.nameGenerator.PrefixedName(repo.BranchGenerateName)
+		}
+
+		if existingPR == nil {
+			if err := u.scm.CreateBranch(ctx, repo.SourceRepo, branch, sha); err != nil {
+				u.metrics.SCMAPIErrorsTotal.WithLabelValues("create_branch").Inc()
+				return fmt.Errorf("failed to create branch: %w", err)
+			}
+		}
+	}
+
+	if len(files) == 1 {
+		for path, update := range files {
+			if err := u.scm.UpdateFile(ctx, repo.SourceRepo, path, branch, update.Content, update.SHA); err != nil {
+				u.metrics.SCMAPIErrorsTotal.WithLabelValues("update_file").Inc()
+				return fmt.Errorf("failed to update file: %w", err)
+			}
+		}
+	} else if err := u.scm.UpdateFiles(ctx, repo.SourceRepo, branch, files); err != nil {
+		u.metrics.SCMAPIErrorsTotal.WithLabelValues("update_files").Inc()
+		return fmt.Errorf("failed to update files: %w", err)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	u.log.Infow("updated repository for image push",
+		"repo", repo.Name,
+		"source_repo", repo.SourceRepo,
+		"branch", branch,
+		"tag", tag,
+		"files", paths,
+	)
+
+	if repo.BranchGenerateName == "" {
+		return nil
+	}
+
+	input := &scm.PullRequestInput{
+		Title: fmt.Sprintf("Image %s updated", repo.Name),
+		Body:  "Automated Image Update",
+		Head:  branch,
+		Base:  repo.SourceBranch,
+	}
+
+	if existingPR != nil {
+		if err := u.scm.UpdatePullRequest(ctx, repo.SourceRepo, existingPR.Number, input); err != nil {
+			u.metrics.SCMAPIErrorsTotal.WithLabelValues("update_pull_request").Inc()
+			return fmt.Errorf("failed to update existing pull request: %w", err)
+		}
+		u.log.Infow("reusing existing pull request for image update", "repo", repo.Name, "pull_request", existingPR.Link)
+		return nil
+	}
+
+	pr, err := u.scm.CreatePullRequest(ctx, repo.SourceRepo, input)
+	if err != nil {
+		u.metrics.SCMAPIErrorsTotal.WithLabelValues("create_pull_request").Inc()
+		return fmt.Errorf("failed to create a pull request: %w", err)
+	}
+	u.metrics.PullRequestsOpen.Inc()
+	u.log.Infow("opened pull request for image update", "repo", repo.Name, "pull_request", pr.Link)
+	return nil
+}
+
+// renderedTarget is the outcome of evaluating one config.Update against a
+// hook: the file it writes to, the key it rewrote, the new image reference,
+// the resulting file content, and the blob SHA of the content it replaces
+// (required by UpdateFile/UpdateFiles to identify the file being replaced).
+type renderedTarget struct {
+	path      string
+	updateKey string
+	image     string
+	content   []byte
+	blobSHA   string
+}
+
+// renderTargets evaluates every one of repo's update targets against the
+// pushed tag, skipping any whose ImageMatch doesn't match the hook's image.
+func (u *Updater) renderTargets(ctx context.Context, repo *config.Repository, hook hooks.Hook, tag string) ([]renderedTarget, error) {
+	var targets []renderedTarget
+	for _, target := range repo.Targets() {
+		if target.ImageMatch != "" {
+			matched, err := regexp.MatchString(target.ImageMatch, hook.GetDockerURL())
+			if err != nil {
+				return nil, fmt.Errorf("invalid image_match %q: %w", target.ImageMatch, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		newImage, err := renderImageRef(target.TagTemplate, hook, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		rw, err := rewriter.ForFormat(target.Format, target.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		content, blobSHA, err := u.scm.GetFile(ctx, repo.SourceRepo, target.FilePath, repo.SourceBranch)
+		if err != nil {
+			u.metrics.SCMAPIErrorsTotal.WithLabelValues("get_file").Inc()
+			return nil, err
+		}
+		updated, err := rw.Rewrite(content, target.UpdateKey, newImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite %s: %w", target.FilePath, err)
+		}
+		targets = append(targets, renderedTarget{
+			path:      target.FilePath,
+			updateKey: target.UpdateKey,
+			image:     newImage,
+			content:   updated,
+			blobSHA:   blobSHA,
+		})
+	}
+	return targets, nil
+}
+
+// defaultTagTemplate reproduces the updater's original, non-templated
+// behaviour of writing "<docker-url>:<tag>".
+const defaultTagTemplate = "{{.Repository}}:{{.Tag}}"
+
+// imageTemplateData is the data made available to an Update's TagTemplate.
+type imageTemplateData struct {
+	Tag        string
+	Digest     string
+	Repository string
+	Owner      string
+}
+
+// renderImageRef renders the new image reference to write for a target,
+// using tmpl (or defaultTagTemplate if tmpl is empty).
+func renderImageRef(tmpl string, hook hooks.Hook, tag string) (string, error) {
+	if tmpl == "" {
+		tmpl = defaultTagTemplate
+	}
+	data := imageTemplateData{
+		Tag:        tag,
+		Repository: hook.GetDockerURL(),
+		Owner:      ownerFromRepository(hook.GetDockerURL()),
+	}
+	if dh, ok := hook.(hooks.DigestHook); ok {
+		data.Digest = dh.GetDigest()
+	}
+
+	t, err := template.New("tag").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse tag template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render tag template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// ownerFromRepository returns the namespace/owner segment of a docker image
+// reference, e.g. "testorg" for "quay.io/testorg/repo".
+func ownerFromRepository(ref string) string {
+	parts := strings.Split(ref, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}